@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+//User is a registered account that can own articles.
+type User struct {
+	Id           int
+	Email        string
+	PasswordHash string
+}
+
+var ErrUserNotFound = errors.New("user not found")
+var ErrUserExists = errors.New("user already registered")
+
+//UserRepository mirrors the ArticleRepository seam: it keeps the in-memory
+//user store swappable for a persistent one later without touching the
+//auth handlers.
+type UserRepository interface {
+	GetByEmail(email string) (User, error)
+	Insert(user User) (User, error)
+}
+
+type memoryUserRepository struct {
+	m     sync.Mutex
+	store []User
+}
+
+func newMemoryUserRepository() *memoryUserRepository {
+	return &memoryUserRepository{}
+}
+
+func (repo *memoryUserRepository) GetByEmail(email string) (User, error) {
+	repo.m.Lock()
+	defer repo.m.Unlock()
+	for _, user := range repo.store {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return User{}, ErrUserNotFound
+}
+
+func (repo *memoryUserRepository) Insert(user User) (User, error) {
+	repo.m.Lock()
+	defer repo.m.Unlock()
+	for _, existing := range repo.store {
+		if existing.Email == user.Email {
+			return User{}, ErrUserExists
+		}
+	}
+	user.Id = len(repo.store)
+	repo.store = append(repo.store, user)
+	return user, nil
+}
+
+//RegisterRequest / LoginRequest are the JSON bodies accepted by /register
+//and /login.
+type RegisterRequest struct {
+	Email    string
+	Password string
+}
+
+type LoginRequest struct {
+	Email    string
+	Password string
+}
+
+type LoginResponse struct {
+	Token string
+}
+
+const tokenValidFor = 24 * time.Hour
+
+type authClaims struct {
+	UserID int `json:"userId"`
+	jwt.RegisteredClaims
+}
+
+//authHandlers wires /register, /login and the requireAuth middleware to a
+//UserRepository and the key used to sign and verify JWTs.
+type authHandlers struct {
+	users      UserRepository
+	signingKey []byte
+}
+
+func newAuthHandlers(users UserRepository, signingKey []byte) *authHandlers {
+	return &authHandlers{users: users, signingKey: signingKey}
+}
+
+//
+//POST - handler to process below curl requests
+//curl -X POST localhost:3000/register -d '{"Email":"a@b.com","Password":"hunter2"}'
+//
+func (a *authHandlers) register(resp http.ResponseWriter, r *http.Request) {
+	bodyBytes, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		resp.WriteHeader(http.StatusInternalServerError)
+		resp.Write([]byte(err.Error()))
+		return
+	}
+	var req RegisterRequest
+	if err := json.Unmarshal(bodyBytes, &req); err != nil {
+		resp.WriteHeader(http.StatusBadRequest)
+		resp.Write([]byte(err.Error()))
+		return
+	}
+	if req.Email == "" || req.Password == "" {
+		resp.WriteHeader(http.StatusBadRequest)
+		resp.Write([]byte("Bad request - Email and Password are required."))
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		resp.WriteHeader(http.StatusInternalServerError)
+		resp.Write([]byte(err.Error()))
+		return
+	}
+	if _, err := a.users.Insert(User{Email: req.Email, PasswordHash: string(hash)}); err != nil {
+		resp.WriteHeader(http.StatusConflict)
+		resp.Write([]byte(err.Error()))
+		return
+	}
+	resp.WriteHeader(http.StatusCreated)
+}
+
+//
+//POST - handler to process below curl requests
+//curl -X POST localhost:3000/login -d '{"Email":"a@b.com","Password":"hunter2"}'
+//
+func (a *authHandlers) login(resp http.ResponseWriter, r *http.Request) {
+	bodyBytes, err := ioutil.ReadAll(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		resp.WriteHeader(http.StatusInternalServerError)
+		resp.Write([]byte(err.Error()))
+		return
+	}
+	var req LoginRequest
+	if err := json.Unmarshal(bodyBytes, &req); err != nil {
+		resp.WriteHeader(http.StatusBadRequest)
+		resp.Write([]byte(err.Error()))
+		return
+	}
+
+	user, err := a.users.GetByEmail(req.Email)
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil {
+		resp.WriteHeader(http.StatusUnauthorized)
+		resp.Write([]byte("Bad request - invalid email or password."))
+		return
+	}
+
+	claims := authClaims{
+		UserID: user.Id,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenValidFor)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(a.signingKey)
+	if err != nil {
+		resp.WriteHeader(http.StatusInternalServerError)
+		resp.Write([]byte(err.Error()))
+		return
+	}
+
+	jsonBody, err := json.Marshal(LoginResponse{Token: token})
+	if err != nil {
+		resp.WriteHeader(http.StatusInternalServerError)
+		resp.Write([]byte(err.Error()))
+		return
+	}
+	resp.Header().Add("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	resp.Write(jsonBody)
+}
+
+type contextKey string
+
+const userIDContextKey contextKey = "userID"
+
+//requireAuth validates the Authorization: Bearer <jwt> header and attaches
+//the signed-in user id to the request context before calling next. Wrap
+//POST /articles (and any future mutating route) with this.
+func (a *authHandlers) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(resp http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		tokenString := strings.TrimPrefix(header, "Bearer ")
+		if tokenString == "" || tokenString == header {
+			resp.WriteHeader(http.StatusUnauthorized)
+			resp.Write([]byte("Bad request - missing bearer token."))
+			return
+		}
+
+		var claims authClaims
+		_, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+			return a.signingKey, nil
+		}, jwt.WithValidMethods([]string{"HS256"}))
+		if err != nil {
+			resp.WriteHeader(http.StatusUnauthorized)
+			resp.Write([]byte("Bad request - invalid or expired token."))
+			return
+		}
+
+		next(resp, r.WithContext(context.WithValue(r.Context(), userIDContextKey, claims.UserID)))
+	}
+}
+
+//userIDFromContext reads the user id requireAuth attached to the request.
+func userIDFromContext(r *http.Request) (int, bool) {
+	id, ok := r.Context().Value(userIDContextKey).(int)
+	return id, ok
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+)
+
+//articleType exposes DomainDataObject over GraphQL so clients can ask for
+//only the fields they need instead of the fixed DtoRespArticle shape.
+var articleType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Article",
+	Fields: graphql.Fields{
+		"id":    &graphql.Field{Type: graphql.Int},
+		"title": &graphql.Field{Type: graphql.String},
+		"date":  &graphql.Field{Type: graphql.String},
+		"body":  &graphql.Field{Type: graphql.String},
+		"tags": &graphql.Field{
+			Type: graphql.NewList(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				domain, ok := p.Source.(DomainDataObject)
+				if !ok {
+					return nil, nil
+				}
+				tags := make([]string, 0, len(domain.Tags))
+				for tag := range domain.Tags {
+					tags = append(tags, tag)
+				}
+				return tags, nil
+			},
+		},
+	},
+})
+
+//tagStatsType is the GraphQL equivalent of DtoResponseTagArticles.
+var tagStatsType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TagStats",
+	Fields: graphql.Fields{
+		"tag":         &graphql.Field{Type: graphql.String},
+		"count":       &graphql.Field{Type: graphql.Int},
+		"articles":    &graphql.Field{Type: graphql.NewList(graphql.Int)},
+		"relatedTags": &graphql.Field{Type: graphql.NewList(graphql.String)},
+	},
+})
+
+//newGraphQLSchema wires the article/articles/tagStats queries to the same
+//h.repo ArticleRepository that backs the REST endpoints.
+func newGraphQLSchema(h *articleHandlers) (graphql.Schema, error) {
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"article": &graphql.Field{
+				Type: articleType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := p.Args["id"].(int)
+					domain, err := h.repo.Get(id)
+					if err != nil {
+						return nil, nil
+					}
+					return domain, nil
+				},
+			},
+			"articles": &graphql.Field{
+				Type: graphql.NewList(articleType),
+				Args: graphql.FieldConfigArgument{
+					"tag":   &graphql.ArgumentConfig{Type: graphql.String},
+					"date":  &graphql.ArgumentConfig{Type: graphql.String, Description: "exact article date, e.g. 2022-04-07 (matches DomainDataObject.Date, not the dashes-stripped YYYYMMDD used by /tags/{tag}/{date})"},
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					tag, _ := p.Args["tag"].(string)
+					date, _ := p.Args["date"].(string)
+					limit, hasLimit := p.Args["limit"].(int)
+
+					//QueryByTagAndDate's date arg follows the /tags/{tag}/{date}
+					//convention (dashes stripped), which isn't the format this
+					//field documents or that DomainDataObject.Date stores. Filter
+					//by tag there, then match date ourselves against the raw
+					//stored value.
+					matched, err := h.repo.QueryByTagAndDate(tag, "")
+					if err != nil {
+						return nil, err
+					}
+					if date != "" {
+						filtered := matched[:0]
+						for _, domain := range matched {
+							if domain.Date == date {
+								filtered = append(filtered, domain)
+							}
+						}
+						matched = filtered
+					}
+					if hasLimit && len(matched) > limit {
+						matched = matched[:limit]
+					}
+					return matched, nil
+				},
+			},
+			"tagStats": &graphql.Field{
+				Type: tagStatsType,
+				Args: graphql.FieldConfigArgument{
+					"tag":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"date": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					tag := p.Args["tag"].(string)
+					date := p.Args["date"].(string)
+					return h.computeTagStats(tag, date), nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+//graphQLRequest is the standard {query, variables} POST body used by most
+//GraphQL clients.
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+//newGraphQLHandler builds the schema once and serves it over POST /graphql.
+func newGraphQLHandler(h *articleHandlers) http.Handler {
+	schema, err := newGraphQLSchema(h)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return http.HandlerFunc(func(resp http.ResponseWriter, r *http.Request) {
+		bodyBytes, err := ioutil.ReadAll(r.Body)
+		defer r.Body.Close()
+		if err != nil {
+			resp.WriteHeader(http.StatusInternalServerError)
+			resp.Write([]byte(err.Error()))
+			return
+		}
+		var req graphQLRequest
+		if err := json.Unmarshal(bodyBytes, &req); err != nil {
+			resp.WriteHeader(http.StatusBadRequest)
+			resp.Write([]byte(err.Error()))
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+		})
+
+		jsonBody, err := json.Marshal(result)
+		if err != nil {
+			resp.WriteHeader(http.StatusInternalServerError)
+			resp.Write([]byte(err.Error()))
+			return
+		}
+		resp.Header().Add("Content-Type", "application/json")
+		resp.WriteHeader(http.StatusOK)
+		resp.Write(jsonBody)
+	})
+}
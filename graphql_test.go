@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+//TestGraphQLArticlesDateArgMatchesStoredFormat guards against the date arg
+//silently requiring the dashes-stripped YYYYMMDD convention that
+//QueryByTagAndDate uses for /tags/{tag}/{date} - the articles query
+//documents and expects the raw YYYY-MM-DD format articles are stored in.
+func TestGraphQLArticlesDateArgMatchesStoredFormat(t *testing.T) {
+	repo := newMemoryRepository()
+	h := &articleHandlers{repo: repo}
+	repo.Insert(DomainDataObject{Date: "2022-04-07", Tags: map[string]int{"testtag": 0}})
+	repo.Insert(DomainDataObject{Date: "2022-04-08", Tags: map[string]int{"testtag": 0}})
+	if err := h.rebuildIndex(); err != nil {
+		t.Fatalf("rebuildIndex() = %v", err)
+	}
+
+	schema, err := newGraphQLSchema(h)
+	if err != nil {
+		t.Fatalf("newGraphQLSchema() = %v", err)
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ articles(tag: "testtag", date: "2022-04-07") { id date } }`,
+	})
+	if len(result.Errors) != 0 {
+		t.Fatalf("query errors: %v", result.Errors)
+	}
+
+	data, ok := result.Data.(map[string]interface{})
+	if !ok {
+		t.Fatalf("unexpected result data: %#v", result.Data)
+	}
+	articles, ok := data["articles"].([]interface{})
+	if !ok {
+		t.Fatalf("unexpected articles field: %#v", data["articles"])
+	}
+	if len(articles) != 1 {
+		t.Fatalf("articles(date: \"2022-04-07\") returned %d results, want 1: %#v", len(articles), articles)
+	}
+}
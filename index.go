@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"sync"
+)
+
+//tagDateIndex is an in-memory secondary index over (tag, date) kept inside
+//articleHandlers so getTags doesn't have to do a full scan of the
+//repository on every request. It is rebuilt from the repository at
+//startup and kept up to date as articles are inserted.
+type tagDateIndex struct {
+	m sync.RWMutex
+	//byDate maps a date (dashes stripped, YYYYMMDD) to the ids of every
+	//article entered that day.
+	byDate map[string][]int
+	//byTagDate maps tag -> date -> ids of the articles on that tag and date.
+	byTagDate map[string]map[string][]int
+}
+
+func newTagDateIndex() *tagDateIndex {
+	return &tagDateIndex{
+		byDate:    make(map[string][]int),
+		byTagDate: make(map[string]map[string][]int),
+	}
+}
+
+//add records domain under its date and every one of its tags. Callers must
+//already hold idx.m if they need it combined with other index mutations;
+//add takes its own lock otherwise.
+func (idx *tagDateIndex) add(domain DomainDataObject) {
+	idx.m.Lock()
+	defer idx.m.Unlock()
+	idx.addLocked(domain)
+}
+
+func (idx *tagDateIndex) addLocked(domain DomainDataObject) {
+	dateKey := strings.ReplaceAll(domain.Date, "-", "")
+	idx.byDate[dateKey] = append(idx.byDate[dateKey], domain.Id)
+	for tag := range domain.Tags {
+		if idx.byTagDate[tag] == nil {
+			idx.byTagDate[tag] = make(map[string][]int)
+		}
+		idx.byTagDate[tag][dateKey] = append(idx.byTagDate[tag][dateKey], domain.Id)
+	}
+}
+
+//idsForTagAndDate returns the ids indexed under tag+date, oldest first.
+func (idx *tagDateIndex) idsForTagAndDate(tag string, date string) []int {
+	idx.m.RLock()
+	defer idx.m.RUnlock()
+	ids := idx.byTagDate[tag][date]
+	out := make([]int, len(ids))
+	copy(out, ids)
+	return out
+}
+
+//rebuildIndex loads every article currently in h.repo and rebuilds the
+//index from scratch. Repository backends that persist data across
+//restarts (bolt, postgres) need this at startup; the in-memory backend
+//just rebuilds an index that's already empty.
+func (h *articleHandlers) rebuildIndex() error {
+	all, err := h.repo.List()
+	if err != nil {
+		return err
+	}
+	idx := newTagDateIndex()
+	idx.m.Lock()
+	for _, domain := range all {
+		idx.addLocked(domain)
+	}
+	idx.m.Unlock()
+	h.index = idx
+	return nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTagDateIndexIdsForTagAndDate(t *testing.T) {
+	idx := newTagDateIndex()
+	idx.add(DomainDataObject{Id: 0, Date: "2022-04-07", Tags: map[string]int{"health": 0}})
+	idx.add(DomainDataObject{Id: 1, Date: "2022-04-07", Tags: map[string]int{"fitness": 0}})
+	idx.add(DomainDataObject{Id: 2, Date: "2022-04-07", Tags: map[string]int{"health": 0}})
+	idx.add(DomainDataObject{Id: 3, Date: "2022-04-08", Tags: map[string]int{"health": 0}})
+
+	got := idx.idsForTagAndDate("health", "20220407")
+	want := []int{0, 2}
+	if len(got) != len(want) {
+		t.Fatalf("idsForTagAndDate() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("idsForTagAndDate() = %v, want %v", got, want)
+		}
+	}
+
+	if got := idx.idsForTagAndDate("missing-tag", "20220407"); len(got) != 0 {
+		t.Fatalf("idsForTagAndDate() for missing tag = %v, want empty", got)
+	}
+}
+
+func TestComputeTagStats(t *testing.T) {
+	repo := newMemoryRepository()
+	h := &articleHandlers{repo: repo}
+	for i := 0; i < 12; i++ {
+		repo.Insert(DomainDataObject{
+			Date: "2022-04-07",
+			Tags: map[string]int{"health": 0, fmt.Sprintf("tag%d", i): 1},
+		})
+	}
+	if err := h.rebuildIndex(); err != nil {
+		t.Fatalf("rebuildIndex() error = %v", err)
+	}
+
+	stats := h.computeTagStats("health", "20220407")
+	if stats.Count != 12 {
+		t.Fatalf("Count = %d, want 12", stats.Count)
+	}
+	if len(stats.Articles) != 10 {
+		t.Fatalf("Articles = %v, want the last 10 ids", stats.Articles)
+	}
+	if stats.Articles[0] != 2 || stats.Articles[len(stats.Articles)-1] != 11 {
+		t.Fatalf("Articles = %v, want ids 2..11", stats.Articles)
+	}
+}
+
+//fullScanTagStats reproduces the pre-index getTags behaviour (scan every
+//article in the repository) so BenchmarkComputeTagStats can show the
+//speedup newTagDateIndex buys over it.
+func fullScanTagStats(repo ArticleRepository, tag string, date string) DtoResponseTagArticles {
+	var tagStore DtoResponseTagArticles
+	tagStore.Tag = tag
+
+	matched, _ := repo.QueryByTagAndDate(tag, date)
+	tagStore.Count = len(matched)
+
+	lastTen := matched
+	if len(lastTen) > 10 {
+		lastTen = lastTen[len(lastTen)-10:]
+	}
+	for _, domain := range lastTen {
+		tagStore.Articles = append(tagStore.Articles, domain.Id)
+	}
+	return tagStore
+}
+
+func benchmarkRepoWith100kArticles(b *testing.B) (*memoryRepository, string) {
+	repo := newMemoryRepository()
+	date := time.Now().Format("2006-01-02")
+	for i := 0; i < 100000; i++ {
+		tags := map[string]int{"science": 0}
+		if i%50 == 0 {
+			tags["health"] = 1
+		}
+		repo.Insert(DomainDataObject{
+			Title: "article " + strconv.Itoa(i),
+			Date:  date,
+			Tags:  tags,
+		})
+	}
+	return repo, date
+}
+
+//BenchmarkComputeTagStatsFullScan measures the pre-chunk0-4 behaviour:
+//getTags scanning every article in the store on each call.
+func BenchmarkComputeTagStatsFullScan(b *testing.B) {
+	repo, date := benchmarkRepoWith100kArticles(b)
+	dateQ := strings.ReplaceAll(date, "-", "")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		fullScanTagStats(repo, "health", dateQ)
+	}
+}
+
+//BenchmarkComputeTagStatsIndex measures the same query served from
+//tagDateIndex, the fix for chunk0-4.
+func BenchmarkComputeTagStatsIndex(b *testing.B) {
+	repo, date := benchmarkRepoWith100kArticles(b)
+	h := &articleHandlers{repo: repo}
+	if err := h.rebuildIndex(); err != nil {
+		b.Fatalf("rebuildIndex() error = %v", err)
+	}
+	dateQ := strings.ReplaceAll(date, "-", "")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.computeTagStats("health", dateQ)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type jobStatus string
+
+const (
+	jobStatusPending jobStatus = "pending"
+	jobStatusDone    jobStatus = "done"
+	jobStatusFailed  jobStatus = "failed"
+)
+
+//postJob tracks one queued POST /articles request so a client can poll
+//GET /jobs/{id} for its outcome instead of blocking on the insert.
+type postJob struct {
+	Id        string
+	Status    jobStatus
+	ArticleId int
+	Error     string
+
+	request  DtoPostRequestArticle
+	authorID int
+}
+
+const (
+	jobQueueSize    = 100
+	jobQueueWorkers = 4
+	//enqueueTimeout bounds how long post() waits for room in the queue
+	//before giving up and returning 503, the way a deadlineTimer caps how
+	//long a caller waits on a cancel channel.
+	enqueueTimeout = 2 * time.Second
+)
+
+//jobQueue is a bounded channel of pending postJobs drained by a fixed pool
+//of worker goroutines inserting into the repository, so POST /articles no
+//longer blocks behind a single global write lock.
+type jobQueue struct {
+	h       *articleHandlers
+	pending chan *postJob
+
+	m      sync.Mutex
+	jobs   map[string]*postJob
+	nextId int
+
+	//insertMu serializes repo.Insert with the matching index.add across
+	//workers so the index stays in ascending-id order; without it two
+	//workers can insert out of order and race each other into index.add.
+	insertMu sync.Mutex
+}
+
+func newJobQueue(h *articleHandlers) *jobQueue {
+	q := &jobQueue{
+		h:       h,
+		pending: make(chan *postJob, jobQueueSize),
+		jobs:    make(map[string]*postJob),
+	}
+	for i := 0; i < jobQueueWorkers; i++ {
+		go q.worker()
+	}
+	return q
+}
+
+func (q *jobQueue) worker() {
+	for job := range q.pending {
+		domain := q.h.ConvertReqDtoToDomain(job.request, job.authorID)
+		q.insertMu.Lock()
+		inserted, err := q.h.repo.Insert(domain)
+		if err == nil {
+			q.h.index.add(inserted)
+		}
+		q.insertMu.Unlock()
+
+		q.m.Lock()
+		if err != nil {
+			job.Status = jobStatusFailed
+			job.Error = err.Error()
+		} else {
+			job.Status = jobStatusDone
+			job.ArticleId = inserted.Id
+		}
+		q.m.Unlock()
+	}
+}
+
+//enqueue admits a new postJob if there is room within enqueueTimeout,
+//otherwise returns accepted=false so the caller can respond 503. The
+//returned postJob is a snapshot, not the map's pointer - worker() keeps
+//mutating the original's Status/ArticleId/Error after this returns, so
+//callers must re-fetch via get() rather than read the returned value later.
+func (q *jobQueue) enqueue(req DtoPostRequestArticle, authorID int) (job postJob, accepted bool) {
+	q.m.Lock()
+	j := &postJob{Id: strconv.Itoa(q.nextId), Status: jobStatusPending, request: req, authorID: authorID}
+	q.nextId++
+	q.jobs[j.Id] = j
+	q.m.Unlock()
+	//Snapshot before the job is ever reachable by a worker - once it's sent
+	//on q.pending a worker may grab it and start mutating it immediately,
+	//so *j can't be read again after the send without racing that worker.
+	snapshot := *j
+
+	ctx, cancel := context.WithTimeout(context.Background(), enqueueTimeout)
+	defer cancel()
+	select {
+	case q.pending <- j:
+		return snapshot, true
+	case <-ctx.Done():
+		q.m.Lock()
+		delete(q.jobs, j.Id)
+		q.m.Unlock()
+		return snapshot, false
+	}
+}
+
+//get returns a snapshot of the job's exported fields, safe to read without
+//q.m since a worker can be mutating the original concurrently.
+func (q *jobQueue) get(id string) (postJob, bool) {
+	q.m.Lock()
+	defer q.m.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return postJob{}, false
+	}
+	return *job, true
+}
+
+//
+//GET - handler to process below curl requests
+//curl http://localhost:3000/jobs/3
+//
+func (q *jobQueue) getJob(resp http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	job, ok := q.get(vars["id"])
+	if !ok {
+		resp.WriteHeader(http.StatusNotFound)
+		resp.Write([]byte("Bad request url - job not found."))
+		return
+	}
+	jsonBody, err := json.Marshal(job)
+	if err != nil {
+		resp.WriteHeader(http.StatusInternalServerError)
+		resp.Write([]byte(err.Error()))
+		return
+	}
+	resp.Header().Add("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusOK)
+	resp.Write(jsonBody)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+//TestJobQueueEnqueueConcurrentMarshal guards against a race between
+//worker() mutating a postJob in place and a caller marshaling the value
+//enqueue() handed back - run with -race. enqueue() must return a
+//snapshot, never the map's live *postJob, since worker() keeps writing
+//Status/ArticleId/Error on that pointer after enqueue() returns.
+func TestJobQueueEnqueueConcurrentMarshal(t *testing.T) {
+	repo := newMemoryRepository()
+	h := &articleHandlers{repo: repo, index: newTagDateIndex()}
+	h.jobs = newJobQueue(h)
+
+	const n = 50
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			job, accepted := h.jobs.enqueue(DtoPostRequestArticle{Title: "t"}, 1)
+			if !accepted {
+				return
+			}
+			if _, err := json.Marshal(job); err != nil {
+				t.Errorf("json.Marshal(job) = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
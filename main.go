@@ -6,9 +6,9 @@ import (
 	"log"
 	"net/http"
 	"strconv"
-	"strings"
-	"sync"
 	"time"
+
+	"github.com/gorilla/mux"
 )
 //Domain object model for receiving Post request data
 type DtoPostRequestArticle struct {
@@ -18,19 +18,21 @@ type DtoPostRequestArticle struct {
 }
 //Data transfer object(DTO) model for responding to request for articles
 type DtoRespArticle struct {
-	Id    int
-	Title string
-	Date  string
-	Body  string
-	Tags  []string
+	Id       int
+	Title    string
+	Date     string
+	Body     string
+	AuthorID int
+	Tags     []string
 }
 //Data transfer object(DTO) model for responding to request for articles
 type DomainDataObject struct {
-	Id    int
-	Title string
-	Date  string
-	Body  string
-	Tags  map[string]int
+	Id       int
+	Title    string
+	Date     string
+	Body     string
+	AuthorID int
+	Tags     map[string]int
 }
 //Data transfer object(DTO) for responding to request for tag specific queries
 type DtoResponseTagArticles struct {
@@ -41,34 +43,9 @@ type DtoResponseTagArticles struct {
 }
 //Wrapper for the store
 type articleHandlers struct {
-	m     sync.Mutex
-	store []DomainDataObject
-}
-//Request parser / router
-func (h *articleHandlers) articles(resp http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case "GET":
-		h.get(resp, r)
-		return
-	case "POST":
-		h.post(resp, r)
-		return
-	default:
-		resp.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-}
-//Request parser / router
-func (h *articleHandlers) tags(resp http.ResponseWriter, r *http.Request) {
-	switch r.Method {
-	case "GET":
-		h.getTags(resp, r)
-		return
-	case "POST":
-	default:
-		resp.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
+	repo  ArticleRepository
+	jobs  *jobQueue
+	index *tagDateIndex
 }
 
 //
@@ -76,50 +53,8 @@ func (h *articleHandlers) tags(resp http.ResponseWriter, r *http.Request) {
 //curl http://localhost:3000/tags/health/20220407
 //
 func (h *articleHandlers) getTags(resp http.ResponseWriter, r *http.Request) {
-	h.m.Lock()
-	defer h.m.Unlock()
-	ss := strings.Split(r.URL.String(), "/")
-	//Only allow valid request curl http://localhost:3000/tags/health/20220407
-	if len(ss) != 4 {
-		resp.WriteHeader(http.StatusBadRequest)
-		resp.Write([]byte("Bad request tag url."))
-		return
-	}
-	dateQ := ss[3]
-	tagQ := ss[2]
-	var tagStore DtoResponseTagArticles
-	tagMap := make(map[string]int)
-	count := 0
-	var ids []int
-	//
-	//Below code will search date and then iterate in tags to find query tag and date
-	//combination
-	//However GraphQL is expected to retrieve data faster and neat way
-	//
-	for i := 0; i < len(h.store); i++ {
-		//clean search date string
-		res := strings.ReplaceAll(h.store[i].Date, "-", "")
-		if res == dateQ { // date check
-			_,pres := h.store[i].Tags[tagQ] 
-			if pres == true {
-				//if we are here we have found a valid date and tag entry 
-				//Now create the return response dto object
-				ids = append(ids, h.store[i].Id )
-				for k,_ := range h.store[i].Tags{
-					tagMap[k] = 0
-				}
-				count++
-			}
-		}
-	}
-
-	//create tag dto
-	tagStore.Count = count
-	tagStore.Tag = tagQ
-	tagStore.Articles = ids
-	for k, _ := range tagMap {
-		tagStore.Related_Tags = append(tagStore.Related_Tags, k)
-	}
+	vars := mux.Vars(r)
+	tagStore := h.computeTagStats(vars["tag"], vars["date"])
 	jsonBody, err := json.Marshal(tagStore)
 	if err != nil {
 		resp.WriteHeader(http.StatusInternalServerError)
@@ -131,6 +66,42 @@ func (h *articleHandlers) getTags(resp http.ResponseWriter, r *http.Request) {
 	resp.Write(jsonBody)
 }
 
+//
+//computeTagStats looks up every article id indexed under tagQ and dateQ
+//(dashes stripped, YYYYMMDD form) in h.index instead of scanning the whole
+//repository, then takes the last 10 of those ids and unions the tag sets
+//of just those articles for Related_Tags. Shared by the REST /tags handler
+//and the /graphql tagStats query so both stay in sync.
+//
+func (h *articleHandlers) computeTagStats(tagQ string, dateQ string) DtoResponseTagArticles {
+	var tagStore DtoResponseTagArticles
+	tagStore.Tag = tagQ
+
+	ids := h.index.idsForTagAndDate(tagQ, dateQ)
+	tagStore.Count = len(ids)
+
+	lastTen := ids
+	if len(lastTen) > 10 {
+		lastTen = lastTen[len(lastTen)-10:]
+	}
+	tagStore.Articles = lastTen
+
+	tagMap := make(map[string]int)
+	for _, id := range lastTen {
+		domain, err := h.repo.Get(id)
+		if err != nil {
+			continue
+		}
+		for k := range domain.Tags {
+			tagMap[k] = 0
+		}
+	}
+	for k := range tagMap {
+		tagStore.Related_Tags = append(tagStore.Related_Tags, k)
+	}
+	return tagStore
+}
+
 
 //
 //GET - handler to process below curl requests
@@ -138,22 +109,22 @@ func (h *articleHandlers) getTags(resp http.ResponseWriter, r *http.Request) {
 //
 func (h *articleHandlers) process_id_request(resp http.ResponseWriter, r *http.Request) {
 
-	h.m.Lock()
-	defer h.m.Unlock()
-	ss := strings.Split(r.URL.String(), "/")
-	number, errConv := strconv.ParseUint(ss[2], 10, 32)
+	vars := mux.Vars(r)
+	number, errConv := strconv.ParseUint(vars["id"], 10, 32)
 	if errConv != nil {
 		resp.WriteHeader(http.StatusBadRequest)
 		resp.Write([]byte("Bad request url."))
 		return
 	}
 	finalIntNum := int(number) //Convert uint64 To int
-	if finalIntNum > len(h.store)-1 {
+
+	domain, err := h.repo.Get(finalIntNum)
+	if err != nil {
 		resp.WriteHeader(http.StatusBadRequest)
 		resp.Write([]byte("Bad request url - index not valid."))
 		return
 	}
-	jsonBody, err := json.Marshal(h.store[finalIntNum])
+	jsonBody, err := json.Marshal(domain)
 	if err != nil {
 		resp.WriteHeader(http.StatusInternalServerError)
 		resp.Write([]byte(err.Error()))
@@ -165,61 +136,74 @@ func (h *articleHandlers) process_id_request(resp http.ResponseWriter, r *http.R
 	resp.Write(jsonBody)
 }
 
+//defaultPageSize is used when the caller omits ?n= or sends a bad value.
+const defaultPageSize = 20
+
 //
 //GET - handler to process below curl requests
-//curl http://localhost:3000/
 //curl http://localhost:3000/articles
+//curl http://localhost:3000/articles?last=9&n=20&tag=health&from=2022-04-01&to=2022-04-30
+//
+//Follows the cursor-based catalog pattern (last id in, Link: rel="next"
+//out) rather than returning the whole store on every call.
 //
 func (h *articleHandlers) get(resp http.ResponseWriter, r *http.Request) {
-
-	ss := strings.Split(r.URL.String(), "/")
-	if len(ss) > 3 {
-		resp.WriteHeader(http.StatusBadRequest)
-		resp.Write([]byte("Bad request url."))
-		return
+	query := r.URL.Query()
+	last := -1 // absent/invalid: start from the beginning, since ids start at 0
+	if v, err := strconv.Atoi(query.Get("last")); err == nil {
+		last = v
 	}
-	if len(ss) == 3 {
-		//Here we have requested to process id based query
-		h.process_id_request(resp, r)
-		return
+	n, err := strconv.Atoi(query.Get("n"))
+	if err != nil || n <= 0 {
+		n = defaultPageSize
+	}
+	tag := query.Get("tag")
+	from := query.Get("from")
+	to := query.Get("to")
+	author := -1
+	if a, err := strconv.Atoi(query.Get("author")); err == nil {
+		author = a
 	}
 
-	h.m.Lock()
-	defer h.m.Unlock()
-
-	respStore := h.CreateRespStoreFromDomainStore()
-	
-	fmt.Println(respStore)
-
-	jsonBody, err := json.Marshal(respStore)
+	page, hasMore, err := h.repo.ListPage(last, n, tag, author, from, to)
 	if err != nil {
 		resp.WriteHeader(http.StatusInternalServerError)
 		resp.Write([]byte(err.Error()))
 		return
 	}
+
+	if hasMore && len(page) > 0 {
+		next := query
+		next.Set("last", strconv.Itoa(page[len(page)-1].Id))
+		next.Set("n", strconv.Itoa(n))
+		resp.Header().Add("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, next.Encode()))
+	}
 	resp.Header().Add("Content-Type", "application/json")
 	resp.WriteHeader(http.StatusOK)
-	resp.Write(jsonBody)
-}
-
-//
-//This function expects to queue multiple clients post request to avoid 
-//write to block
-// 
-func (h *articleHandlers) enqueuPostRequests(resp http.ResponseWriter, r *http.Request) {
 
+	//Stream the page out as a JSON array one article at a time instead of
+	//building the whole []DtoRespArticle slice up front.
+	resp.Write([]byte("["))
+	enc := json.NewEncoder(resp)
+	for i, domain := range page {
+		if i > 0 {
+			resp.Write([]byte(","))
+		}
+		enc.Encode(domainToRespArticle(domain))
+	}
+	resp.Write([]byte("]"))
 }
 
 //
 //Post handler
 //respective post body is attached with postman query
 //POST Domain object carries only Title,body and tags
+//Queues the insert on h.jobs instead of writing inline, and hands back a
+//job id the caller can poll at GET /jobs/{id} for the resulting article id.
 //Example - of curl post request
 //curl -H HOST "localhost:3000/articles" -X "POST" -d '{"Title":"Sugar","Body":"MyJson body is pretty form-at-ed","Tags":["health","fitness","science"]}'
 //
 func (h *articleHandlers) post(resp http.ResponseWriter, r *http.Request) {
-	h.m.Lock()
-	defer h.m.Unlock()
 	jsonBodyBytes, err := ioutil.ReadAll(r.Body)
 	defer r.Body.Close()
 	if err != nil {
@@ -235,20 +219,40 @@ func (h *articleHandlers) post(resp http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//Adapter to translate request
-	domain := h.ConvertReqDtoToDomain(dtoReq)
-	h.store = append(h.store, domain)
+	authorID, ok := userIDFromContext(r)
+	if !ok {
+		resp.WriteHeader(http.StatusUnauthorized)
+		resp.Write([]byte("Bad request - missing authenticated user."))
+		return
+	}
+
+	job, accepted := h.jobs.enqueue(dtoReq, authorID)
+	if !accepted {
+		resp.WriteHeader(http.StatusServiceUnavailable)
+		resp.Write([]byte("job queue is full, try again later"))
+		return
+	}
+
+	jsonBody, err := json.Marshal(job)
+	if err != nil {
+		resp.WriteHeader(http.StatusInternalServerError)
+		resp.Write([]byte(err.Error()))
+		return
+	}
+	resp.Header().Add("Content-Type", "application/json")
+	resp.WriteHeader(http.StatusAccepted)
+	resp.Write(jsonBody)
 }
 //
 //Request DTO is converted to domain dto before saving in repository
 //Adapter pattern to transform data from one form to other
 //
-func (h *articleHandlers) ConvertReqDtoToDomain(dtoReq DtoPostRequestArticle) DomainDataObject {
+func (h *articleHandlers) ConvertReqDtoToDomain(dtoReq DtoPostRequestArticle, authorID int) DomainDataObject {
 	var domain DomainDataObject
-	domain.Id = len(h.store)
 	domain.Title = dtoReq.Title
 	domain.Date = time.Now().Format("2006-01-02")
 	domain.Body = dtoReq.Body
+	domain.AuthorID = authorID
 	domain.Tags = make(map[string]int)
 	i := 0
 	for ;i<len(dtoReq.Tags); i++{
@@ -258,55 +262,75 @@ func (h *articleHandlers) ConvertReqDtoToDomain(dtoReq DtoPostRequestArticle) Do
 }
 
 //
-//Domain data is converted to DTO before saving in repository
+//Domain data is converted to DTO before sending in a response
 //Adapter pattern to transform data from one form to other
 //
-func (h *articleHandlers) CreateRespStoreFromDomainStore() []DtoRespArticle {
-	
-	var s []DtoRespArticle
-	for i:=0; i< len(h.store); i++  {
-		domain := h.store[i]
-		var dto DtoRespArticle
-		dto.Id = domain.Id
-		dto.Title = domain.Title
-		dto.Date = domain.Date
-		dto.Body = domain.Body
-		dto.Tags = make([]string, len(h.store[i].Tags))
-		for tag,_ := range h.store[i].Tags {
-			dto.Tags = append(dto.Tags, tag)
-		}
-		s = append(s, dto)
-	} 
-	return s
+func domainToRespArticle(domain DomainDataObject) DtoRespArticle {
+	var dto DtoRespArticle
+	dto.Id = domain.Id
+	dto.Title = domain.Title
+	dto.Date = domain.Date
+	dto.Body = domain.Body
+	dto.AuthorID = domain.AuthorID
+	dto.Tags = make([]string, 0, len(domain.Tags))
+	for tag := range domain.Tags {
+		dto.Tags = append(dto.Tags, tag)
+	}
+	return dto
 }
 
-//Get default store data
-func newArticleHandlers() *articleHandlers {
-	return &articleHandlers{
-		store: []DomainDataObject{
-			DomainDataObject{
-				Id:    0,
-				Title: "latest science shows that potato chips are better for you than sugar",
-				Date:  time.Now().Format("2006-01-02"),
-				Body:  "some text, potentially containing simple markup about how potato chips are great",
-				Tags: map[string]int{
-					"health":0,
-					"fitness":1,
-					"science":2,
-				},
+//newArticleHandlers wraps repo, seeds the default sample article the first
+//time it sees an empty store, then rebuilds the tag/date index from
+//whatever repo already holds - covering both the just-seeded memory
+//backend and a bolt/postgres backend that already has data on disk.
+func newArticleHandlers(repo ArticleRepository) *articleHandlers {
+	h := &articleHandlers{repo: repo}
+	if existing, err := repo.List(); err == nil && len(existing) == 0 {
+		repo.Insert(DomainDataObject{
+			Title: "latest science shows that potato chips are better for you than sugar",
+			Date:  time.Now().Format("2006-01-02"),
+			Body:  "some text, potentially containing simple markup about how potato chips are great",
+			Tags: map[string]int{
+				"health":0,
+				"fitness":1,
+				"science":2,
 			},
-		},
+		})
+	}
+	if err := h.rebuildIndex(); err != nil {
+		log.Fatal(err)
 	}
+	return h
 }
 func main() {
 	default_port := "3000"
+	cfg := parseConfig()
+	repo, err := newArticleRepository(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 	//Initializing default store
-	articleHandlers := newArticleHandlers()
-	//Router handler registration
-	http.HandleFunc("/", articleHandlers.articles)
-	http.HandleFunc("/tags/", articleHandlers.tags)
+	articleHandlers := newArticleHandlers(repo)
+	articleHandlers.jobs = newJobQueue(articleHandlers)
+	if cfg.JWTSecret == "" {
+		log.Fatal("jwt-secret is empty: set --jwt-secret or $JWT_SECRET before starting the server")
+	}
+	//Users are kept in-memory for now, same as the articles default backend
+	userRepo := newMemoryUserRepository()
+	authHandlers := newAuthHandlers(userRepo, []byte(cfg.JWTSecret))
+	//Router handler registration - routes are declared once here instead of
+	//being parsed out of r.URL.String() by hand in every handler
+	r := mux.NewRouter()
+	r.HandleFunc("/articles", articleHandlers.get).Methods("GET")
+	r.HandleFunc("/articles", authHandlers.requireAuth(articleHandlers.post)).Methods("POST")
+	r.HandleFunc("/articles/{id}", articleHandlers.process_id_request).Methods("GET")
+	r.HandleFunc("/tags/{tag}/{date}", articleHandlers.getTags).Methods("GET")
+	r.HandleFunc("/jobs/{id}", articleHandlers.jobs.getJob).Methods("GET")
+	r.HandleFunc("/register", authHandlers.register).Methods("POST")
+	r.HandleFunc("/login", authHandlers.login).Methods("POST")
+	r.Handle("/graphql", newGraphQLHandler(articleHandlers))
 	fmt.Println("server running at  :" + default_port)
-	if err := http.ListenAndServe("localhost:"+default_port, nil); err != nil {
+	if err := http.ListenAndServe("localhost:"+default_port, r); err != nil {
 		log.Fatal(err)
 	}
 }
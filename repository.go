@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+//ErrArticleNotFound is returned by ArticleRepository.Get when no article
+//exists for the requested id.
+var ErrArticleNotFound = errors.New("article not found")
+
+//ArticleRepository decouples articleHandlers from how articles are stored
+//so the in-memory slice can be swapped for an embedded or networked
+//database without touching any handler code.
+type ArticleRepository interface {
+	Get(id int) (DomainDataObject, error)
+	List() ([]DomainDataObject, error)
+	Insert(domain DomainDataObject) (DomainDataObject, error)
+	QueryByTagAndDate(tag string, date string) ([]DomainDataObject, error)
+	//ListPage returns up to n articles with id > last (oldest-first cursor,
+	//following the Docker distribution Repositories(ctx, repos, last)
+	//pattern), optionally filtered by tag, by author (pass -1 for no
+	//filter), and by a [from, to] date range (YYYY-MM-DD, either bound
+	//optional). hasMore reports whether another page follows so callers can
+	//emit a Link: rel="next" header.
+	ListPage(last int, n int, tag string, author int, from string, to string) (items []DomainDataObject, hasMore bool, err error)
+}
+
+//memoryRepository is the original behaviour: an in-memory slice guarded by
+//a mutex. Lost on restart, used as the default storage backend.
+type memoryRepository struct {
+	m     sync.Mutex
+	store []DomainDataObject
+}
+
+func newMemoryRepository() *memoryRepository {
+	return &memoryRepository{}
+}
+
+func (repo *memoryRepository) Get(id int) (DomainDataObject, error) {
+	repo.m.Lock()
+	defer repo.m.Unlock()
+	if id < 0 || id > len(repo.store)-1 {
+		return DomainDataObject{}, ErrArticleNotFound
+	}
+	return repo.store[id], nil
+}
+
+func (repo *memoryRepository) List() ([]DomainDataObject, error) {
+	repo.m.Lock()
+	defer repo.m.Unlock()
+	out := make([]DomainDataObject, len(repo.store))
+	copy(out, repo.store)
+	return out, nil
+}
+
+func (repo *memoryRepository) Insert(domain DomainDataObject) (DomainDataObject, error) {
+	repo.m.Lock()
+	defer repo.m.Unlock()
+	domain.Id = len(repo.store)
+	repo.store = append(repo.store, domain)
+	return domain, nil
+}
+
+func (repo *memoryRepository) QueryByTagAndDate(tag string, date string) ([]DomainDataObject, error) {
+	repo.m.Lock()
+	defer repo.m.Unlock()
+	var matched []DomainDataObject
+	for i := 0; i < len(repo.store); i++ {
+		if date != "" && strings.ReplaceAll(repo.store[i].Date, "-", "") != date {
+			continue
+		}
+		if tag != "" {
+			if _, pres := repo.store[i].Tags[tag]; !pres {
+				continue
+			}
+		}
+		matched = append(matched, repo.store[i])
+	}
+	return matched, nil
+}
+
+func (repo *memoryRepository) ListPage(last int, n int, tag string, author int, from string, to string) ([]DomainDataObject, bool, error) {
+	repo.m.Lock()
+	defer repo.m.Unlock()
+	var page []DomainDataObject
+	hasMore := false
+	for i := 0; i < len(repo.store); i++ {
+		domain := repo.store[i]
+		if domain.Id <= last {
+			continue
+		}
+		if tag != "" {
+			if _, pres := domain.Tags[tag]; !pres {
+				continue
+			}
+		}
+		if author >= 0 && domain.AuthorID != author {
+			continue
+		}
+		if from != "" && domain.Date < from {
+			continue
+		}
+		if to != "" && domain.Date > to {
+			continue
+		}
+		if len(page) == n {
+			hasMore = true
+			break
+		}
+		page = append(page, domain)
+	}
+	return page, hasMore, nil
+}
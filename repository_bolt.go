@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.etcd.io/bbolt"
+)
+
+//articlesBucket holds one JSON-encoded DomainDataObject per article,
+//keyed by its big-endian encoded id.
+var articlesBucket = []byte("articles")
+
+//boltArticleRepository is the embedded-storage backend: articles persist
+//to a single file under work-dir and survive restarts without needing a
+//separate database process.
+type boltArticleRepository struct {
+	db *bbolt.DB
+}
+
+func newBoltArticleRepository(workDir string) (*boltArticleRepository, error) {
+	if err := os.MkdirAll(workDir, 0o755); err != nil {
+		return nil, err
+	}
+	db, err := bbolt.Open(filepath.Join(workDir, "articles.db"), 0o600, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(articlesBucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &boltArticleRepository{db: db}, nil
+}
+
+func itob(id int) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(id))
+	return b
+}
+
+func (repo *boltArticleRepository) Get(id int) (DomainDataObject, error) {
+	var domain DomainDataObject
+	err := repo.db.View(func(tx *bbolt.Tx) error {
+		raw := tx.Bucket(articlesBucket).Get(itob(id))
+		if raw == nil {
+			return ErrArticleNotFound
+		}
+		return json.Unmarshal(raw, &domain)
+	})
+	return domain, err
+}
+
+func (repo *boltArticleRepository) List() ([]DomainDataObject, error) {
+	var out []DomainDataObject
+	err := repo.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(articlesBucket).ForEach(func(_ []byte, raw []byte) error {
+			var domain DomainDataObject
+			if err := json.Unmarshal(raw, &domain); err != nil {
+				return err
+			}
+			out = append(out, domain)
+			return nil
+		})
+	})
+	return out, err
+}
+
+func (repo *boltArticleRepository) Insert(domain DomainDataObject) (DomainDataObject, error) {
+	err := repo.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(articlesBucket)
+		nextID, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		domain.Id = int(nextID) - 1
+		raw, err := json.Marshal(domain)
+		if err != nil {
+			return err
+		}
+		return bucket.Put(itob(domain.Id), raw)
+	})
+	return domain, err
+}
+
+func (repo *boltArticleRepository) QueryByTagAndDate(tag string, date string) ([]DomainDataObject, error) {
+	all, err := repo.List()
+	if err != nil {
+		return nil, err
+	}
+	var matched []DomainDataObject
+	for _, domain := range all {
+		if date != "" && strings.ReplaceAll(domain.Date, "-", "") != date {
+			continue
+		}
+		if tag != "" {
+			if _, pres := domain.Tags[tag]; !pres {
+				continue
+			}
+		}
+		matched = append(matched, domain)
+	}
+	return matched, nil
+}
+
+func (repo *boltArticleRepository) ListPage(last int, n int, tag string, author int, from string, to string) ([]DomainDataObject, bool, error) {
+	var page []DomainDataObject
+	hasMore := false
+	err := repo.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(articlesBucket).Cursor()
+		for k, raw := c.Seek(itob(last + 1)); k != nil; k, raw = c.Next() {
+			var domain DomainDataObject
+			if err := json.Unmarshal(raw, &domain); err != nil {
+				return err
+			}
+			if tag != "" {
+				if _, pres := domain.Tags[tag]; !pres {
+					continue
+				}
+			}
+			if author >= 0 && domain.AuthorID != author {
+				continue
+			}
+			if from != "" && domain.Date < from {
+				continue
+			}
+			if to != "" && domain.Date > to {
+				continue
+			}
+			if len(page) == n {
+				hasMore = true
+				break
+			}
+			page = append(page, domain)
+		}
+		return nil
+	})
+	return page, hasMore, err
+}
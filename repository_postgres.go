@@ -0,0 +1,161 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+//postgresArticleRepository is the networked-storage backend, used when
+//multiple instances of this service need to share one article store.
+type postgresArticleRepository struct {
+	db *sql.DB
+}
+
+func newPostgresArticleRepository(dsn string) (*postgresArticleRepository, error) {
+	if dsn == "" {
+		return nil, fmt.Errorf("postgres storage backend requires --postgres-dsn")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS articles (
+		id        SERIAL PRIMARY KEY,
+		title     TEXT NOT NULL,
+		date      TEXT NOT NULL,
+		body      TEXT NOT NULL,
+		author_id INTEGER NOT NULL DEFAULT 0,
+		tags      JSONB NOT NULL
+	)`); err != nil {
+		return nil, err
+	}
+	return &postgresArticleRepository{db: db}, nil
+}
+
+func (repo *postgresArticleRepository) scanRow(row interface {
+	Scan(dest ...interface{}) error
+}) (DomainDataObject, error) {
+	var domain DomainDataObject
+	var tagsJSON []byte
+	if err := row.Scan(&domain.Id, &domain.Title, &domain.Date, &domain.Body, &domain.AuthorID, &tagsJSON); err != nil {
+		return domain, err
+	}
+	domain.Tags = make(map[string]int)
+	if err := json.Unmarshal(tagsJSON, &domain.Tags); err != nil {
+		return domain, err
+	}
+	return domain, nil
+}
+
+func (repo *postgresArticleRepository) Get(id int) (DomainDataObject, error) {
+	row := repo.db.QueryRow(`SELECT id, title, date, body, author_id, tags FROM articles WHERE id = $1`, id)
+	domain, err := repo.scanRow(row)
+	if err == sql.ErrNoRows {
+		return domain, ErrArticleNotFound
+	}
+	return domain, err
+}
+
+func (repo *postgresArticleRepository) List() ([]DomainDataObject, error) {
+	rows, err := repo.db.Query(`SELECT id, title, date, body, author_id, tags FROM articles ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []DomainDataObject
+	for rows.Next() {
+		domain, err := repo.scanRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, domain)
+	}
+	return out, rows.Err()
+}
+
+func (repo *postgresArticleRepository) Insert(domain DomainDataObject) (DomainDataObject, error) {
+	tagsJSON, err := json.Marshal(domain.Tags)
+	if err != nil {
+		return domain, err
+	}
+	err = repo.db.QueryRow(
+		`INSERT INTO articles (title, date, body, author_id, tags) VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+		domain.Title, domain.Date, domain.Body, domain.AuthorID, tagsJSON,
+	).Scan(&domain.Id)
+	return domain, err
+}
+
+func (repo *postgresArticleRepository) QueryByTagAndDate(tag string, date string) ([]DomainDataObject, error) {
+	all, err := repo.List()
+	if err != nil {
+		return nil, err
+	}
+	var matched []DomainDataObject
+	for _, domain := range all {
+		if date != "" && strings.ReplaceAll(domain.Date, "-", "") != date {
+			continue
+		}
+		if tag != "" {
+			if _, pres := domain.Tags[tag]; !pres {
+				continue
+			}
+		}
+		matched = append(matched, domain)
+	}
+	return matched, nil
+}
+
+func (repo *postgresArticleRepository) ListPage(last int, n int, tag string, author int, from string, to string) ([]DomainDataObject, bool, error) {
+	query := `SELECT id, title, date, body, author_id, tags FROM articles WHERE id > $1`
+	args := []interface{}{last}
+	if tag != "" {
+		args = append(args, tag)
+		query += fmt.Sprintf(" AND tags ? $%d", len(args))
+	}
+	if author >= 0 {
+		args = append(args, author)
+		query += fmt.Sprintf(" AND author_id = $%d", len(args))
+	}
+	if from != "" {
+		args = append(args, from)
+		query += fmt.Sprintf(" AND date >= $%d", len(args))
+	}
+	if to != "" {
+		args = append(args, to)
+		query += fmt.Sprintf(" AND date <= $%d", len(args))
+	}
+	args = append(args, n+1)
+	query += fmt.Sprintf(" ORDER BY id LIMIT $%d", len(args))
+
+	rows, err := repo.db.Query(query, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var page []DomainDataObject
+	for rows.Next() {
+		domain, err := repo.scanRow(rows)
+		if err != nil {
+			return nil, false, err
+		}
+		page = append(page, domain)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(page) > n
+	if hasMore {
+		page = page[:n]
+	}
+	return page, hasMore, nil
+}